@@ -50,19 +50,43 @@ type ActionExample struct {
 	Expected    string                 `json:"expected,omitempty"`
 }
 
+// Context carries scope information about the caller requesting menu
+// entries or executors, so discovery can be narrowed to what's actually
+// relevant instead of flooding every menu with every registered provider.
+type Context struct {
+	// User is the identifier of the current user.
+	User string
+
+	// Workspace is the active workspace or project identifier.
+	Workspace string
+
+	// Environment is the name of the environment the caller is operating
+	// in, e.g. "prod", "staging", "dev".
+	Environment string
+
+	// Features lists feature flags currently enabled for the caller.
+	Features map[string]bool
+}
+
+// HasFeature reports whether the named feature flag is enabled in this
+// context.
+func (c Context) HasFeature(name string) bool {
+	return c.Features[name]
+}
+
 // DynamicProvider provides dynamic menu entries
 type DynamicProvider interface {
 	// GetID returns the unique identifier for this provider
 	GetID() string
 
-	// GetEntries generates menu entries based on the given parameter
-	GetEntries(param string) ([]types.MenuEntry, error)
+	// GetEntries generates menu entries based on the given context and parameter
+	GetEntries(ctx Context, param string) ([]types.MenuEntry, error)
 
 	// GetDescription returns a description of what this provider does
 	GetDescription() string
 
-	// SupportsParam checks if this provider can handle the given parameter
-	SupportsParam(param string) bool
+	// Supports checks if this provider can handle the given parameter in the given context
+	Supports(ctx Context, param string) bool
 }
 
 // InteractiveFunction represents a function that can be called from menus
@@ -112,6 +136,10 @@ type ActionRegistry interface {
 	// GetAllFunctions returns all registered functions
 	GetAllFunctions() map[string]InteractiveFunction
 
+	// GetProvidersForContext returns only the providers relevant to ctx, so
+	// menus aren't flooded with entries no plugin would offer in this scope
+	GetProvidersForContext(ctx Context) []DynamicProvider
+
 	// ExecuteAction executes an action using the appropriate executor
 	ExecuteAction(executorID, actionID string, params map[string]interface{}) (*types.ActionResult, error)
 }
@@ -0,0 +1,212 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// channelFetchTimeout bounds how long a single HTTP fetch is allowed to take
+// when aggregating channels and repositories.
+const channelFetchTimeout = 30 * time.Second
+
+// PluginChannel points to a JSON document listing multiple PluginRepository
+// entries. Channels let community maintainers curate sets of repositories
+// without forcing users to individually reconfigure MOPS.
+type PluginChannel struct {
+	Name    string `yaml:"name"`
+	URL     string `yaml:"url"`
+	Enabled bool   `yaml:"enabled"`
+}
+
+// PluginChannels is an ordered collection of channels to aggregate.
+type PluginChannels []PluginChannel
+
+// PluginVersion describes a single installable version of a PluginPackage.
+type PluginVersion struct {
+	Version  string   `json:"version" yaml:"version"`
+	URL      string   `json:"url" yaml:"url"`
+	Requires []string `json:"requires,omitempty" yaml:"requires,omitempty"`
+
+	// Hash is the catalog's advertised sha256 digest of the archive at URL,
+	// hex-encoded and unprefixed. When set, installation refuses to proceed
+	// if the downloaded archive's own digest doesn't match it.
+	Hash string `json:"hash,omitempty" yaml:"hash,omitempty"`
+}
+
+// PluginPackage describes a plugin as advertised by a repository or channel.
+type PluginPackage struct {
+	Name           string          `json:"name" yaml:"name"`
+	Description    string          `json:"description,omitempty" yaml:"description,omitempty"`
+	Author         string          `json:"author,omitempty" yaml:"author,omitempty"`
+	Tags           []string        `json:"tags,omitempty" yaml:"tags,omitempty"`
+	PluginVersions []PluginVersion `json:"versions" yaml:"versions"`
+}
+
+// channelDocument is the JSON document a PluginChannel URL resolves to.
+type channelDocument struct {
+	Repositories []PluginRepository `json:"repositories"`
+}
+
+// repositoryDocument is the JSON document a PluginRepository URL resolves to.
+type repositoryDocument struct {
+	Packages []PluginPackage `json:"packages"`
+}
+
+// Fetch loads the repository's document, from the local filesystem or over
+// HTTP(S) depending on r.Type, and returns its advertised packages keyed by
+// name.
+func (r *PluginRepository) Fetch() (map[string]PluginPackage, error) {
+	var doc repositoryDocument
+	var err error
+	if r.Type == "local" {
+		err = readLocalJSON(r.URL, &doc)
+	} else {
+		err = fetchJSON(r.URL, &doc)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fetch repository %s: %w", r.Name, err)
+	}
+
+	catalog := make(map[string]PluginPackage, len(doc.Packages))
+	for _, pkg := range doc.Packages {
+		catalog[pkg.Name] = pkg
+	}
+	return catalog, nil
+}
+
+// PluginRepositories is an ordered collection of repositories to aggregate.
+type PluginRepositories []PluginRepository
+
+// Fetch concurrently downloads every enabled repository and merges the union
+// of all advertised packages into a single in-memory catalog keyed by
+// package name. Repositories are merged in order, so a later repository's
+// entry for a given package name overrides an earlier one.
+func (r PluginRepositories) Fetch() (map[string]PluginPackage, error) {
+	type result struct {
+		index   int
+		catalog map[string]PluginPackage
+		err     error
+	}
+
+	results := make([]result, len(r))
+	var wg sync.WaitGroup
+	for i, repo := range r {
+		if !repo.Enabled {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, repo PluginRepository) {
+			defer wg.Done()
+			catalog, err := repo.Fetch()
+			results[i] = result{index: i, catalog: catalog, err: err}
+		}(i, repo)
+	}
+	wg.Wait()
+
+	merged := make(map[string]PluginPackage)
+	var errs []error
+	for _, res := range results {
+		if res.err != nil {
+			errs = append(errs, res.err)
+			continue
+		}
+		for name, pkg := range res.catalog {
+			merged[name] = pkg
+		}
+	}
+
+	if len(errs) > 0 {
+		return merged, fmt.Errorf("%d repositories failed: %w", len(errs), joinErrors(errs))
+	}
+	return merged, nil
+}
+
+// Fetch downloads the channel's document and concurrently fetches every
+// enabled repository it advertises, returning their merged catalog.
+func (c *PluginChannel) Fetch() (map[string]PluginPackage, error) {
+	var doc channelDocument
+	if err := fetchJSON(c.URL, &doc); err != nil {
+		return nil, fmt.Errorf("fetch channel %s: %w", c.Name, err)
+	}
+
+	return PluginRepositories(doc.Repositories).Fetch()
+}
+
+// Fetch concurrently downloads every enabled channel and merges the union of
+// all advertised packages into a single in-memory catalog keyed by package
+// name.
+func (c PluginChannels) Fetch() (map[string]PluginPackage, error) {
+	type result struct {
+		catalog map[string]PluginPackage
+		err     error
+	}
+
+	results := make([]result, len(c))
+	var wg sync.WaitGroup
+	for i, channel := range c {
+		if !channel.Enabled {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, channel PluginChannel) {
+			defer wg.Done()
+			catalog, err := channel.Fetch()
+			results[i] = result{catalog: catalog, err: err}
+		}(i, channel)
+	}
+	wg.Wait()
+
+	merged := make(map[string]PluginPackage)
+	var errs []error
+	for _, res := range results {
+		if res.err != nil {
+			errs = append(errs, res.err)
+			continue
+		}
+		for name, pkg := range res.catalog {
+			merged[name] = pkg
+		}
+	}
+
+	if len(errs) > 0 {
+		return merged, fmt.Errorf("%d channels failed: %w", len(errs), joinErrors(errs))
+	}
+	return merged, nil
+}
+
+func fetchJSON(url string, dest interface{}) error {
+	client := http.Client{Timeout: channelFetchTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s", resp.StatusCode, url)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(dest)
+}
+
+// readLocalJSON reads and decodes the JSON document at the filesystem path
+// path, for repositories configured with Type "local".
+func readLocalJSON(path string, dest interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dest)
+}
+
+func joinErrors(errs []error) error {
+	msg := errs[0].Error()
+	for _, err := range errs[1:] {
+		msg += "; " + err.Error()
+	}
+	return fmt.Errorf("%s", msg)
+}
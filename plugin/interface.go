@@ -105,10 +105,11 @@ type PluginMetadata struct {
 	APIVersion     string `yaml:"api_version"`
 
 	// Runtime requirements
-	RequiresNetwork        bool     `yaml:"requires_network,omitempty"`
-	RequiresSystemCommands bool     `yaml:"requires_system_commands,omitempty"`
-	SystemCommands         []string `yaml:"system_commands,omitempty"`
-	Dependencies           []string `yaml:"dependencies,omitempty"`
+	RequiresNetwork        bool               `yaml:"requires_network,omitempty"`
+	RequiresSystemCommands bool               `yaml:"requires_system_commands,omitempty"`
+	SystemCommands         []string           `yaml:"system_commands,omitempty"`
+	AllowedPaths           []string           `yaml:"allowed_paths,omitempty"`
+	Dependencies           []PluginDependency `yaml:"dependencies,omitempty"`
 
 	// Build information
 	BuildInfo *BuildInfo `yaml:"build_info,omitempty"`
@@ -116,6 +117,43 @@ type PluginMetadata struct {
 	// Categories and tags
 	Categories []string `yaml:"categories,omitempty"`
 	Tags       []string `yaml:"tags,omitempty"`
+
+	// Context predicates, evaluated by ActionRegistry.GetProvidersForContext
+	// so this plugin's providers are only surfaced where relevant
+	Contexts         []string `yaml:"contexts,omitempty"`
+	RequiresFeatures []string `yaml:"requires_features,omitempty"`
+}
+
+// MatchesContext reports whether this plugin is relevant to ctx: if
+// Contexts is non-empty, ctx.Environment must be one of them, and every
+// feature in RequiresFeatures must be enabled in ctx.
+func (m *PluginMetadata) MatchesContext(ctx actions.Context) bool {
+	if len(m.Contexts) > 0 && !containsString(m.Contexts, ctx.Environment) {
+		return false
+	}
+	for _, feature := range m.RequiresFeatures {
+		if !ctx.HasFeature(feature) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// PluginDependency declares that a plugin requires another plugin (or the
+// "mops" core itself) whose version satisfies a semver range, e.g.
+// {Name: "mops", Range: ">=1.0.0 <2.0.0"}.
+type PluginDependency struct {
+	Name  string `yaml:"name"`
+	Range string `yaml:"range"`
 }
 
 // BuildInfo contains information about how the plugin was built
@@ -165,9 +203,16 @@ type LoaderConfig struct {
 
 // PluginRepository defines a source for plugins
 type PluginRepository struct {
-	Name     string `yaml:"name"`
-	URL      string `yaml:"url"`
-	Type     string `yaml:"type"` // "github", "local", "http"
+	Name string `yaml:"name"`
+	URL  string `yaml:"url"`
+
+	// Type selects how URL is resolved into the repository document:
+	// "local" treats URL as a filesystem path; "http" (the default, also
+	// used for "" and "github") treats URL as an HTTP(S) address fetched
+	// directly, so a "github" repository must point at a raw document URL
+	// (e.g. a raw.githubusercontent.com link) rather than a repo slug —
+	// MOPS does not call the GitHub API on its own.
+	Type     string `yaml:"type"`
 	Enabled  bool   `yaml:"enabled"`
 	Priority int    `yaml:"priority"`
 }
@@ -203,8 +248,8 @@ func DefaultLoaderConfig() *LoaderConfig {
 			"~/.mops/plugins",
 		},
 		Security: &SecurityConfig{
-			AllowUnsigned:  true, // For development
-			EnableSandbox:  false,
+			AllowUnsigned: true, // For development
+			EnableSandbox: false,
 			DefaultPermissions: &Permissions{
 				NetworkAccess:    true,
 				FileSystemAccess: true,
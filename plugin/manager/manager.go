@@ -0,0 +1,518 @@
+// Package manager implements the install/upgrade/enable/disable/remove
+// lifecycle for plugins, mirroring a container-style plugin management
+// surface on top of the channel/repository catalog and dependency resolver.
+package manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/totmicro/mops-sdk/plugin"
+	pluginpkg "github.com/totmicro/mops-sdk/plugin/pkg"
+	"github.com/totmicro/mops-sdk/plugin/resolver"
+	"github.com/totmicro/mops-sdk/plugin/signing"
+	"github.com/totmicro/mops-sdk/plugin/store"
+)
+
+// stateFileName is the per-plugin state file written into its managed
+// directory, tracking enabled/disabled status independent of uninstall.
+const stateFileName = "state.json"
+
+// downloadTimeout bounds how long a single artifact/signature download is
+// allowed to take, mirroring channelFetchTimeout's bound on catalog fetches.
+const downloadTimeout = 30 * time.Second
+
+// InstalledPlugin describes a plugin the Manager has installed into one of
+// LoaderConfig.PluginDirectories.
+type InstalledPlugin struct {
+	Name        string    `json:"name"`
+	Version     string    `json:"version"`
+	Path        string    `json:"path"`
+	Digest      string    `json:"digest,omitempty"`
+	Enabled     bool      `json:"enabled"`
+	InstalledAt time.Time `json:"installed_at"`
+	Requires    []string  `json:"requires,omitempty"`
+}
+
+// Manager installs, upgrades, and tracks the lifecycle of plugins on disk.
+type Manager struct {
+	config  *plugin.LoaderConfig
+	catalog resolver.Catalog
+}
+
+// New creates a Manager that installs plugins under config's directories
+// and resolves them against catalog.
+func New(config *plugin.LoaderConfig, catalog resolver.Catalog) *Manager {
+	return &Manager{config: config, catalog: catalog}
+}
+
+// Install resolves ref (either "name" or "name@version") against the
+// catalog, downloads the winning artifact (and those of its resolved
+// dependencies), extracts each into the managed plugin directory, and writes
+// its state file: enabled for a fresh install, while a reinstall over an
+// existing plugin keeps that plugin's previous enabled/disabled state.
+func (m *Manager) Install(ref string) error {
+	name, pinned := splitRef(ref)
+
+	catalog := m.catalog
+	if pinned != "" {
+		pkg, ok := catalog[name]
+		if !ok {
+			return fmt.Errorf("install %s: unknown plugin %q", ref, name)
+		}
+		restricted, err := restrictToVersion(catalog, name, pkg, pinned)
+		if err != nil {
+			return fmt.Errorf("install %s: %w", ref, err)
+		}
+		catalog = restricted
+	}
+
+	resolved, err := resolver.Resolve([]string{name}, catalog)
+	if err != nil {
+		return fmt.Errorf("install %s: %w", ref, err)
+	}
+
+	order, err := installOrder(resolved)
+	if err != nil {
+		return fmt.Errorf("install %s: %w", ref, err)
+	}
+	for _, depName := range order {
+		if err := m.installResolved(depName, resolved[depName]); err != nil {
+			return fmt.Errorf("install %s: %w", ref, err)
+		}
+	}
+	return nil
+}
+
+// Upgrade re-resolves name against the full catalog (ignoring the version
+// currently installed) and swaps in the newest version that satisfies every
+// range declared within name's own dependency tree; ranges that other
+// installed plugins declare on name are not consulted. The swap removes the
+// old plugin directory and then renames the newly extracted one into place,
+// so it is not atomic: between the two steps the plugin is not installed.
+func (m *Manager) Upgrade(name string) error {
+	resolved, err := resolver.Resolve([]string{name}, m.catalog)
+	if err != nil {
+		return fmt.Errorf("upgrade %s: %w", name, err)
+	}
+
+	order, err := installOrder(resolved)
+	if err != nil {
+		return fmt.Errorf("upgrade %s: %w", name, err)
+	}
+	for _, depName := range order {
+		if err := m.installResolved(depName, resolved[depName]); err != nil {
+			return fmt.Errorf("upgrade %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Enable flips a previously installed plugin's state to enabled without
+// reinstalling it.
+func (m *Manager) Enable(name string) error {
+	return m.setEnabled(name, true)
+}
+
+// Disable flips a previously installed plugin's state to disabled without
+// uninstalling it.
+func (m *Manager) Disable(name string) error {
+	return m.setEnabled(name, false)
+}
+
+// Remove deletes a plugin's managed directory entirely.
+func (m *Manager) Remove(name string) error {
+	dir, err := m.findInstalled(name)
+	if err != nil {
+		return fmt.Errorf("remove %s: %w", name, err)
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("remove %s: %w", name, err)
+	}
+	return nil
+}
+
+// List returns every plugin installed under the configured plugin
+// directories.
+func (m *Manager) List() ([]InstalledPlugin, error) {
+	var installed []InstalledPlugin
+
+	for _, root := range m.config.PluginDirectories {
+		entries, err := os.ReadDir(root)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("list plugins: %w", err)
+		}
+
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+			state, err := readState(filepath.Join(root, entry.Name()))
+			if err != nil {
+				continue
+			}
+			installed = append(installed, *state)
+		}
+	}
+
+	return installed, nil
+}
+
+// Inspect returns the installed state for a single plugin by name.
+func (m *Manager) Inspect(name string) (*InstalledPlugin, error) {
+	installed, err := m.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range installed {
+		if p.Name == name {
+			return &p, nil
+		}
+	}
+	return nil, fmt.Errorf("inspect %s: not installed", name)
+}
+
+func (m *Manager) installResolved(name string, v plugin.PluginVersion) error {
+	root := m.pluginDirectory()
+	dest := filepath.Join(root, name)
+
+	// A fresh install defaults to enabled, but an upgrade or reinstall over
+	// a plugin the user had Disable'd must not silently re-enable it, so
+	// read whatever was there before install replaces it below.
+	enabled := true
+	if prior, err := readState(dest); err == nil {
+		enabled = prior.Enabled
+	}
+
+	archivePath, err := download(v.URL)
+	if err != nil {
+		return fmt.Errorf("download %s@%s: %w", name, v.Version, err)
+	}
+	defer os.Remove(archivePath)
+
+	digest, err := m.verifyArchive(archivePath, v.URL)
+	if err != nil {
+		return fmt.Errorf("verify %s@%s: %w", name, v.Version, err)
+	}
+	if v.Hash != "" && digest != v.Hash {
+		return fmt.Errorf("verify %s@%s: archive digest %s does not match catalog hash %s", name, v.Version, digest, v.Hash)
+	}
+
+	blobStore, err := store.New(filepath.Join(root, ".store"))
+	if err != nil {
+		return err
+	}
+
+	if pluginpkg.IsArchive(v.URL) {
+		if err := m.verifyManifestHashes(archivePath, blobStore); err != nil {
+			return fmt.Errorf("verify %s@%s: %w", name, v.Version, err)
+		}
+	}
+
+	if _, err := blobStore.Put(archivePath); err != nil {
+		return fmt.Errorf("store %s@%s: %w", name, v.Version, err)
+	}
+
+	tmp := dest + ".tmp"
+	if err := os.RemoveAll(tmp); err != nil {
+		return err
+	}
+	if err := unpack(archivePath, v.URL, tmp); err != nil {
+		return fmt.Errorf("extract %s@%s: %w", name, v.Version, err)
+	}
+
+	if err := os.RemoveAll(dest); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		return err
+	}
+
+	state := InstalledPlugin{
+		Name:        name,
+		Version:     v.Version,
+		Path:        dest,
+		Digest:      digest,
+		Enabled:     enabled,
+		InstalledAt: time.Now().UTC(),
+		Requires:    v.Requires,
+	}
+	return writeState(dest, &state)
+}
+
+// verifyArchive computes archivePath's content digest and, when the
+// security config lists required signature keys, fetches and checks the
+// archive's detached minisign signature against them before allowing the
+// install to proceed. When it lists no keys but forbids unsigned plugins,
+// the install is refused without fetching a signature.
+func (m *Manager) verifyArchive(archivePath, url string) (string, error) {
+	digest, err := store.Digest(archivePath)
+	if err != nil {
+		return "", err
+	}
+
+	sec := m.config.Security
+	mustVerify := sec != nil && len(sec.RequiredSignatures) > 0
+	if !mustVerify && (sec == nil || sec.AllowUnsigned) {
+		return digest, nil
+	}
+	if !mustVerify {
+		return "", fmt.Errorf("unsigned plugins are not allowed and no required signature keys are configured")
+	}
+
+	sigPath, err := download(url + ".minisig")
+	if err != nil {
+		return "", fmt.Errorf("fetch signature: %w", err)
+	}
+	defer os.Remove(sigPath)
+
+	sigData, err := os.ReadFile(sigPath)
+	if err != nil {
+		return "", err
+	}
+	sig, err := signing.ParseSignature(sigData)
+	if err != nil {
+		return "", err
+	}
+
+	required := make([]signing.RequiredKey, 0, len(sec.RequiredSignatures))
+	for _, entry := range sec.RequiredSignatures {
+		key, err := signing.ParseRequiredKey(entry)
+		if err != nil {
+			return "", err
+		}
+		required = append(required, *key)
+	}
+
+	if err := signing.Verify(digest, sig, required); err != nil {
+		return "", err
+	}
+	return digest, nil
+}
+
+// verifyManifestHashes reads archivePath's plugin.yaml manifest and checks
+// its own BuildInfo against the archive it shipped in: the recomputed
+// digest of the bundled .so must match BuildInfo.PluginHash, and every
+// dependency with a declared DependencyInfo.Hash must already be present
+// and intact in store, catching a tampered or substituted transitive
+// dependency before this plugin is installed. Callers must install
+// dependencies before dependents (see installOrder) for the latter check to
+// find anything.
+func (m *Manager) verifyManifestHashes(archivePath string, blobStore *store.Store) error {
+	metadata, err := pluginpkg.Inspect(archivePath)
+	if err != nil {
+		return fmt.Errorf("inspect manifest: %w", err)
+	}
+	if metadata.BuildInfo == nil {
+		return nil
+	}
+
+	if metadata.BuildInfo.PluginHash != "" {
+		soDigest, err := pluginpkg.SOHash(archivePath)
+		if err != nil {
+			return err
+		}
+		if err := store.VerifyPluginHash(soDigest, metadata.BuildInfo); err != nil {
+			return err
+		}
+	}
+
+	return blobStore.VerifyDependencies(metadata.BuildInfo.Dependencies)
+}
+
+func (m *Manager) setEnabled(name string, enabled bool) error {
+	dir, err := m.findInstalled(name)
+	if err != nil {
+		return err
+	}
+	state, err := readState(dir)
+	if err != nil {
+		return err
+	}
+	state.Enabled = enabled
+	return writeState(dir, state)
+}
+
+func (m *Manager) findInstalled(name string) (string, error) {
+	for _, root := range m.config.PluginDirectories {
+		dir := filepath.Join(root, name)
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir, nil
+		}
+	}
+	return "", fmt.Errorf("%q is not installed", name)
+}
+
+// pluginDirectory returns the directory new plugins are installed into: the
+// first configured PluginDirectories entry.
+func (m *Manager) pluginDirectory() string {
+	if len(m.config.PluginDirectories) == 0 {
+		return "."
+	}
+	return m.config.PluginDirectories[0]
+}
+
+// installOrder topologically sorts resolved's plugin names so that every
+// plugin's dependencies precede it. installResolved verifies a plugin's
+// declared DependencyInfo hashes against the blobstore, which only works if
+// those dependencies were already installed (and so already Put into the
+// store); iterating resolved itself would do this in Go's nondeterministic
+// map order.
+func installOrder(resolved map[string]plugin.PluginVersion) ([]string, error) {
+	names := make([]string, 0, len(resolved))
+	for name := range resolved {
+		names = append(names, name)
+	}
+	sort.Strings(names) // deterministic iteration order before the topological sort
+
+	ordered := make([]string, 0, len(names))
+	visited := make(map[string]bool)
+	visiting := make(map[string]bool)
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		if visited[name] {
+			return nil
+		}
+		v, ok := resolved[name]
+		if !ok {
+			return nil // not part of this resolution, e.g. the implicit "mops" core
+		}
+		if visiting[name] {
+			return fmt.Errorf("dependency cycle detected at %q", name)
+		}
+
+		visiting[name] = true
+		for _, req := range v.Requires {
+			depName, _, err := resolver.SplitRequirement(req)
+			if err != nil {
+				return err
+			}
+			if err := visit(depName); err != nil {
+				return err
+			}
+		}
+		delete(visiting, name)
+
+		visited[name] = true
+		ordered = append(ordered, name)
+		return nil
+	}
+
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+func splitRef(ref string) (name, pinnedVersion string) {
+	if idx := strings.LastIndex(ref, "@"); idx > 0 {
+		return ref[:idx], ref[idx+1:]
+	}
+	return ref, ""
+}
+
+// restrictToVersion returns a copy of catalog where name's entry only
+// advertises the requested version, so the resolver is forced to pick it.
+// It errors if requested isn't one of name's published versions, rather
+// than silently leaving the catalog unrestricted.
+func restrictToVersion(catalog resolver.Catalog, name string, pkg plugin.PluginPackage, requested string) (resolver.Catalog, error) {
+	restricted := make(resolver.Catalog, len(catalog))
+	for k, v := range catalog {
+		restricted[k] = v
+	}
+
+	for _, v := range pkg.PluginVersions {
+		if v.Version == requested {
+			pkg.PluginVersions = []plugin.PluginVersion{v}
+			restricted[name] = pkg
+			return restricted, nil
+		}
+	}
+	return nil, fmt.Errorf("version %q not found", requested)
+}
+
+func readState(dir string) (*InstalledPlugin, error) {
+	data, err := os.ReadFile(filepath.Join(dir, stateFileName))
+	if err != nil {
+		return nil, err
+	}
+	var state InstalledPlugin
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("parse state for %s: %w", dir, err)
+	}
+	return &state, nil
+}
+
+func writeState(dir string, state *InstalledPlugin) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, stateFileName), data, 0o644)
+}
+
+func download(url string) (string, error) {
+	client := http.Client{Timeout: downloadTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %d from %s", resp.StatusCode, url)
+	}
+
+	tmp, err := os.CreateTemp("", "mops-plugin-*.zip")
+	if err != nil {
+		return "", err
+	}
+	defer tmp.Close()
+
+	if _, err := io.Copy(tmp, resp.Body); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return tmp.Name(), nil
+}
+
+// unpack installs a downloaded artifact into dest, transparently accepting
+// either a .mopsplugin archive or a bare legacy .so file. sourceURL is used
+// to recognize which form artifactPath is in and, for a bare .so, to name
+// the installed file.
+func unpack(artifactPath, sourceURL, dest string) error {
+	if pluginpkg.IsArchive(sourceURL) {
+		return pluginpkg.Extract(artifactPath, dest)
+	}
+	return installLegacySO(artifactPath, sourceURL, dest)
+}
+
+// installLegacySO places a bare .so download into dest under its original
+// file name, matching how the loader discovered single-file plugins before
+// the .mopsplugin archive format existed.
+func installLegacySO(soPath, sourceURL, dest string) error {
+	if err := os.MkdirAll(dest, 0o755); err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(soPath)
+	if err != nil {
+		return err
+	}
+
+	target := filepath.Join(dest, filepath.Base(sourceURL))
+	return os.WriteFile(target, data, 0o755)
+}
@@ -0,0 +1,255 @@
+// Package pkg implements the .mopsplugin archive format: a zip file
+// containing a plugin.yaml manifest (a serialized plugin.PluginMetadata),
+// the compiled .so, and an assets/ tree.
+package pkg
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/totmicro/mops-sdk/plugin"
+)
+
+// ManifestName is the file name of the serialized PluginMetadata inside a
+// .mopsplugin archive.
+const ManifestName = "plugin.yaml"
+
+// AssetsDir is the conventional directory for a plugin's bundled assets.
+const AssetsDir = "assets"
+
+// Extension is the file extension .mopsplugin archives are expected to use.
+const Extension = ".mopsplugin"
+
+// Build packages dir into a .mopsplugin archive at destArchive. dir must
+// contain a plugin.yaml manifest and exactly one .so file, and may contain
+// an assets/ tree; all three are copied into the archive root.
+func Build(dir, destArchive string) error {
+	manifestPath := filepath.Join(dir, ManifestName)
+	if _, err := os.Stat(manifestPath); err != nil {
+		return fmt.Errorf("build %s: missing %s: %w", dir, ManifestName, err)
+	}
+
+	soPath, err := findSharedObject(dir)
+	if err != nil {
+		return fmt.Errorf("build %s: %w", dir, err)
+	}
+
+	out, err := os.Create(destArchive)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	w := zip.NewWriter(out)
+
+	if err := addFile(w, manifestPath, ManifestName); err != nil {
+		return err
+	}
+	if err := addFile(w, soPath, filepath.Base(soPath)); err != nil {
+		return err
+	}
+
+	assetsPath := filepath.Join(dir, AssetsDir)
+	if info, err := os.Stat(assetsPath); err == nil && info.IsDir() {
+		if err := addDir(w, assetsPath, AssetsDir); err != nil {
+			return err
+		}
+	}
+
+	return w.Close()
+}
+
+// Inspect reads only the plugin.yaml manifest out of archive, without
+// extracting the rest.
+func Inspect(archive string) (*plugin.PluginMetadata, error) {
+	r, err := zip.OpenReader(archive)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		if f.Name != ManifestName {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		var metadata plugin.PluginMetadata
+		if err := yaml.Unmarshal(data, &metadata); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", ManifestName, err)
+		}
+		return &metadata, nil
+	}
+
+	return nil, fmt.Errorf("%s: missing %s", archive, ManifestName)
+}
+
+// SOHash computes the sha256 digest of the .so file bundled in archive,
+// hex-encoded and unprefixed. This is what BuildInfo.PluginHash is expected
+// to match: hashing the whole archive instead would be a fixed point, since
+// the manifest carrying the hash lives inside the archive being hashed.
+func SOHash(archive string) (string, error) {
+	r, err := zip.OpenReader(archive)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		if !strings.HasSuffix(f.Name, ".so") {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return "", err
+		}
+		h := sha256.New()
+		_, err = io.Copy(h, rc)
+		rc.Close()
+		if err != nil {
+			return "", err
+		}
+		return hex.EncodeToString(h.Sum(nil)), nil
+	}
+
+	return "", fmt.Errorf("%s: no .so file found", archive)
+}
+
+// Extract unpacks archive into dest, rejecting any entry that would escape
+// dest via "..", an absolute path, or a symlink.
+func Extract(archive, dest string) error {
+	r, err := zip.OpenReader(archive)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	if err := os.MkdirAll(dest, 0o755); err != nil {
+		return err
+	}
+	cleanDest := filepath.Clean(dest)
+
+	for _, f := range r.File {
+		if filepath.IsAbs(f.Name) {
+			return fmt.Errorf("refusing to extract absolute path entry %q", f.Name)
+		}
+		if f.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("refusing to extract symlink entry %q", f.Name)
+		}
+
+		target := filepath.Join(dest, f.Name)
+		if target != cleanDest && !strings.HasPrefix(target, cleanDest+string(os.PathSeparator)) {
+			return fmt.Errorf("refusing to extract entry outside destination: %q", f.Name)
+		}
+
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+
+		if err := extractFile(f, target); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func extractFile(f *zip.File, target string) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.Mode())
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, rc)
+	return err
+}
+
+// IsArchive reports whether path looks like a .mopsplugin archive rather
+// than a bare legacy .so file.
+func IsArchive(path string) bool {
+	return strings.HasSuffix(path, Extension)
+}
+
+// CacheDir returns the directory an archive should be extracted into,
+// keyed by BuildInfo.PluginHash so repeated loads of identical content
+// reuse the same extraction instead of re-extracting every time.
+func CacheDir(cacheRoot string, info *plugin.BuildInfo) (string, error) {
+	if info == nil || info.PluginHash == "" {
+		return "", fmt.Errorf("cannot key cache directory: no plugin hash available")
+	}
+	return filepath.Join(cacheRoot, info.PluginHash), nil
+}
+
+func findSharedObject(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".so") {
+			return filepath.Join(dir, entry.Name()), nil
+		}
+	}
+	return "", fmt.Errorf("no .so file found")
+}
+
+func addFile(w *zip.Writer, path, name string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	f, err := w.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	return err
+}
+
+func addDir(w *zip.Writer, root, prefix string) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		return addFile(w, path, filepath.ToSlash(filepath.Join(prefix, rel)))
+	})
+}
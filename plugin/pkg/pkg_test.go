@@ -0,0 +1,147 @@
+package pkg
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeZip builds a zip archive at path containing one entry per name/body
+// pair, writing raw headers so entries with path-traversal names can be
+// constructed the same way a malicious archive would.
+func writeZip(t *testing.T, path string, entries map[string]string) {
+	t.Helper()
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("create %s: %v", path, err)
+	}
+	defer f.Close()
+
+	w := zip.NewWriter(f)
+	for name, body := range entries {
+		fw, err := w.Create(name)
+		if err != nil {
+			t.Fatalf("create entry %s: %v", name, err)
+		}
+		if _, err := fw.Write([]byte(body)); err != nil {
+			t.Fatalf("write entry %s: %v", name, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("close zip: %v", err)
+	}
+}
+
+func TestExtractRejectsPathTraversal(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "evil.mopsplugin")
+	writeZip(t, archive, map[string]string{
+		"../../etc/passwd": "pwned",
+	})
+
+	dest := filepath.Join(dir, "dest")
+	if err := Extract(archive, dest); err == nil {
+		t.Fatal("Extract accepted a path-traversal entry, want error")
+	}
+
+	// "../../etc/passwd" relative to dir/dest resolves into dir's parent.
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dir), "etc", "passwd")); !os.IsNotExist(err) {
+		t.Fatal("Extract wrote outside dest despite returning an error")
+	}
+}
+
+func writeZipSymlink(t *testing.T, path, name, target string) {
+	t.Helper()
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("create %s: %v", path, err)
+	}
+	defer f.Close()
+
+	w := zip.NewWriter(f)
+	fh := &zip.FileHeader{Name: name, Method: zip.Deflate}
+	fh.SetMode(os.ModeSymlink | 0o777)
+	fw, err := w.CreateHeader(fh)
+	if err != nil {
+		t.Fatalf("create entry %s: %v", name, err)
+	}
+	if _, err := fw.Write([]byte(target)); err != nil {
+		t.Fatalf("write entry %s: %v", name, err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("close zip: %v", err)
+	}
+}
+
+func TestExtractRejectsSymlink(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "evil.mopsplugin")
+	writeZipSymlink(t, archive, "link", "/etc/passwd")
+
+	dest := filepath.Join(dir, "dest")
+	if err := Extract(archive, dest); err == nil {
+		t.Fatal("Extract accepted a symlink entry, want error")
+	}
+
+	if _, err := os.Lstat(filepath.Join(dest, "link")); !os.IsNotExist(err) {
+		t.Fatal("Extract wrote a symlink despite returning an error")
+	}
+}
+
+func TestExtractRejectsAbsolutePath(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "evil.mopsplugin")
+	writeZip(t, archive, map[string]string{
+		"/etc/passwd": "pwned",
+	})
+
+	dest := filepath.Join(dir, "dest")
+	if err := Extract(archive, dest); err == nil {
+		t.Fatal("Extract accepted an absolute path entry, want error")
+	}
+}
+
+func TestExtractWritesWellFormedArchive(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "good.mopsplugin")
+	writeZip(t, archive, map[string]string{
+		ManifestName:            "name: ok\n",
+		"assets/icon.png":       "binary-ish",
+		"assets/nested/doc.txt": "hello",
+	})
+
+	dest := filepath.Join(dir, "dest")
+	if err := Extract(archive, dest); err != nil {
+		t.Fatalf("Extract returned error for a well-formed archive: %v", err)
+	}
+
+	for name, want := range map[string]string{
+		ManifestName:            "name: ok\n",
+		"assets/icon.png":       "binary-ish",
+		"assets/nested/doc.txt": "hello",
+	} {
+		got, err := os.ReadFile(filepath.Join(dest, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatalf("read extracted %s: %v", name, err)
+		}
+		if string(got) != want {
+			t.Errorf("extracted %s = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestIsArchive(t *testing.T) {
+	tests := map[string]bool{
+		"plugin.mopsplugin": true,
+		"plugin.so":         false,
+		"":                  false,
+	}
+	for path, want := range tests {
+		if got := IsArchive(path); got != want {
+			t.Errorf("IsArchive(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package plugin
+
+import "fmt"
+
+// PrivilegeNegotiator is implemented by the host application to decide how
+// much of a plugin's requested Permissions to actually grant. A loader
+// should call ConfirmPrivileges between Install and Initialize whenever a
+// plugin's PluginMetadata.RequiresNetwork, RequiresSystemCommands, or
+// declared AllowedPaths/SystemCommands exceed what's currently granted by
+// SecurityConfig.DefaultPermissions.
+type PrivilegeNegotiator interface {
+	// ConfirmPrivileges presents the plugin and its requested permissions
+	// to the user (or a policy) and returns the subset actually granted.
+	// granted is what's already configured by default, so a negotiator
+	// that wants to deny the escalation can fall back to it instead of
+	// stripping permissions the plugin never asked to have revoked. The
+	// loader enforces whatever is returned, even if it grants less than
+	// requested.
+	ConfirmPrivileges(metadata PluginMetadata, requested, granted Permissions) (Permissions, error)
+}
+
+// AutoGrantPolicy is a non-interactive PrivilegeNegotiator for CI and other
+// unattended environments.
+type AutoGrantPolicy string
+
+const (
+	// AutoGrantDeny grants nothing beyond what was already configured.
+	AutoGrantDeny AutoGrantPolicy = "deny"
+
+	// AutoGrantDeclared grants exactly what the plugin's own metadata
+	// declares it requires, no more.
+	AutoGrantDeclared AutoGrantPolicy = "allow-declared"
+
+	// AutoGrantAll grants every permission that was requested.
+	AutoGrantAll AutoGrantPolicy = "allow-all"
+)
+
+// ConfirmPrivileges implements PrivilegeNegotiator for AutoGrantPolicy.
+func (p AutoGrantPolicy) ConfirmPrivileges(metadata PluginMetadata, requested, granted Permissions) (Permissions, error) {
+	switch p {
+	case AutoGrantAll:
+		return requested, nil
+	case AutoGrantDeclared:
+		return declaredPermissions(metadata), nil
+	case AutoGrantDeny:
+		return granted, nil
+	default:
+		return Permissions{}, fmt.Errorf("unknown auto-grant policy %q", p)
+	}
+}
+
+// NegotiatePrivileges determines the permissions to grant a plugin given
+// its declared requirements, the permissions already granted by default,
+// and a negotiator. If the plugin's declared requirements don't exceed
+// what's already granted, the negotiator is not consulted at all.
+func NegotiatePrivileges(metadata PluginMetadata, granted Permissions, negotiator PrivilegeNegotiator) (Permissions, error) {
+	requested := declaredPermissionsOver(metadata, granted)
+	if !exceedsGranted(granted, requested) {
+		return granted, nil
+	}
+	if negotiator == nil {
+		return Permissions{}, fmt.Errorf("plugin %q requests privileges beyond the configured defaults and no PrivilegeNegotiator is configured", metadata.Name)
+	}
+	return negotiator.ConfirmPrivileges(metadata, requested, granted)
+}
+
+// declaredPermissions builds the Permissions a plugin's own metadata says
+// it needs, independent of anything already granted.
+func declaredPermissions(metadata PluginMetadata) Permissions {
+	return Permissions{
+		NetworkAccess:   metadata.RequiresNetwork,
+		SystemCommands:  metadata.RequiresSystemCommands,
+		AllowedPaths:    metadata.AllowedPaths,
+		AllowedCommands: metadata.SystemCommands,
+	}
+}
+
+// declaredPermissionsOver layers a plugin's declared requirements on top of
+// whatever is already granted, so the negotiator sees the full request
+// rather than just the delta.
+func declaredPermissionsOver(metadata PluginMetadata, granted Permissions) Permissions {
+	requested := granted
+	if metadata.RequiresNetwork {
+		requested.NetworkAccess = true
+	}
+	if metadata.RequiresSystemCommands {
+		requested.SystemCommands = true
+	}
+	requested.AllowedPaths = mergeUnique(requested.AllowedPaths, metadata.AllowedPaths)
+	requested.AllowedCommands = mergeUnique(requested.AllowedCommands, metadata.SystemCommands)
+	return requested
+}
+
+// exceedsGranted reports whether requested asks for more than granted
+// already allows.
+func exceedsGranted(granted, requested Permissions) bool {
+	if requested.NetworkAccess && !granted.NetworkAccess {
+		return true
+	}
+	if requested.SystemCommands && !granted.SystemCommands {
+		return true
+	}
+	if requested.FileSystemAccess && !granted.FileSystemAccess {
+		return true
+	}
+	return !containsAll(granted.AllowedPaths, requested.AllowedPaths) ||
+		!containsAll(granted.AllowedCommands, requested.AllowedCommands)
+}
+
+func containsAll(have, want []string) bool {
+	set := make(map[string]bool, len(have))
+	for _, h := range have {
+		set[h] = true
+	}
+	for _, w := range want {
+		if !set[w] {
+			return false
+		}
+	}
+	return true
+}
+
+func mergeUnique(a, b []string) []string {
+	if len(a) == 0 && len(b) == 0 {
+		return nil
+	}
+	seen := make(map[string]bool, len(a)+len(b))
+	merged := make([]string, 0, len(a)+len(b))
+	for _, v := range a {
+		if !seen[v] {
+			seen[v] = true
+			merged = append(merged, v)
+		}
+	}
+	for _, v := range b {
+		if !seen[v] {
+			seen[v] = true
+			merged = append(merged, v)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,277 @@
+// Package resolver selects a concrete plugin.PluginVersion for each plugin
+// in a requested set, and every transitive dependency, such that all
+// declared semver ranges are satisfied.
+package resolver
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/totmicro/mops-sdk/plugin"
+	"github.com/totmicro/mops-sdk/version"
+)
+
+// corePluginName is the implicit dependency every plugin has on MOPS itself.
+const corePluginName = "mops"
+
+// Catalog maps plugin name to the package advertised for it, as produced by
+// aggregating PluginChannel/PluginRepository Fetch calls.
+type Catalog map[string]plugin.PluginPackage
+
+// ResolutionError reports that no available version of a plugin satisfies a
+// dependency range required elsewhere in the resolution.
+type ResolutionError struct {
+	Plugin     string // the plugin that declared the requirement
+	Dependency string // the plugin that could not be satisfied
+	Range      string // the range that went unsatisfied
+}
+
+func (e *ResolutionError) Error() string {
+	return fmt.Sprintf("no version of %q satisfies range %q required by %q", e.Dependency, e.Range, e.Plugin)
+}
+
+// requirement records one "name must satisfy rng" constraint discovered
+// during resolution, and who declared it, so a later conflict can be
+// reported against the requirement that actually caused it.
+type requirement struct {
+	rng        string
+	requiredBy string
+}
+
+// Resolve selects one concrete plugin.PluginVersion per requested plugin,
+// and every transitive dependency, such that every PluginDependency range is
+// satisfied. It walks the dependency DAG depth-first, trying the newest
+// matching version of each plugin first and backtracking to the next oldest
+// candidate when a choice makes a downstream range unsatisfiable.
+//
+// Because two requested plugins can share a dependency with different
+// ranges, a name already locked by one branch is re-resolved (not just
+// rejected) whenever a later range conflicts with it, this time picking the
+// newest version satisfying every range collected for that name so far.
+func Resolve(requested []string, catalog Catalog) (map[string]plugin.PluginVersion, error) {
+	selected := make(map[string]plugin.PluginVersion)
+	visiting := make(map[string]bool)
+	constraints := make(map[string][]requirement)
+
+	var resolveOne func(name, rng, requiredBy string) error
+	resolveOne = func(name, rng, requiredBy string) error {
+		if name == corePluginName {
+			if rangeMatches(rng, version.CurrentAPIVersion) {
+				return nil
+			}
+			return &ResolutionError{Plugin: requiredBy, Dependency: corePluginName, Range: rng}
+		}
+
+		constraints[name] = append(constraints[name], requirement{rng: rng, requiredBy: requiredBy})
+
+		if v, ok := selected[name]; ok && rangeMatches(rng, v.Version) {
+			return nil
+		}
+
+		if visiting[name] {
+			return fmt.Errorf("dependency cycle detected at %q", name)
+		}
+
+		pkg, ok := catalog[name]
+		if !ok {
+			return &ResolutionError{Plugin: requiredBy, Dependency: name, Range: rng}
+		}
+
+		visiting[name] = true
+		defer delete(visiting, name)
+
+		// Drop any prior pick; it no longer satisfies every constraint.
+		// Its own requirements also contributed ranges to name's
+		// dependencies (e.g. shared@2 committed depX>=2), and those must
+		// go too, or a discarded candidate's stale range can make an
+		// otherwise-solvable re-resolution look unsatisfiable.
+		purgeContribution(name, selected, constraints)
+
+		// subtreeErr remembers the most recent failure from a candidate whose
+		// own version satisfied every constraint on name: when every
+		// candidate is individually valid, the real conflict lies deeper in
+		// that candidate's dependency subtree, and subtreeErr already names
+		// the plugin/range pair actually responsible for it.
+		var subtreeErr error
+
+		for _, candidate := range sortedVersions(pkg.PluginVersions) {
+			if !satisfiesAll(constraints[name], candidate.Version) {
+				continue
+			}
+
+			// Snapshot before committing to candidate: if its subtree fails
+			// partway through, resolveRequires may have already selected
+			// and added constraints for dependencies several levels down.
+			// Restoring to this snapshot unwinds all of that, rather than
+			// just name's own entry, before the next candidate is tried.
+			beforeSelected := cloneSelected(selected)
+			beforeConstraints := cloneConstraints(constraints)
+
+			selected[name] = candidate
+			if err := resolveRequires(name, candidate, resolveOne); err == nil {
+				return nil
+			} else {
+				subtreeErr = err
+			}
+
+			selected = beforeSelected
+			constraints = beforeConstraints
+		}
+
+		if subtreeErr != nil {
+			return subtreeErr
+		}
+
+		return conflictError(name, pkg, constraints[name])
+	}
+
+	for _, name := range requested {
+		if err := resolveOne(name, "", "<requested>"); err != nil {
+			return nil, err
+		}
+	}
+
+	return selected, nil
+}
+
+// resolveRequires resolves every dependency declared by a candidate version,
+// each of which is formatted as "name range", e.g. "logging-plugin >=2.0.0".
+func resolveRequires(name string, candidate plugin.PluginVersion, resolveOne func(name, rng, requiredBy string) error) error {
+	for _, req := range candidate.Requires {
+		depName, depRange, err := SplitRequirement(req)
+		if err != nil {
+			return err
+		}
+		if err := resolveOne(depName, depRange, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SplitRequirement parses one entry of PluginVersion.Requires, formatted as
+// "name range" (e.g. "logging-plugin >=2.0.0") or bare "name" for an
+// unconstrained requirement, into its plugin name and range.
+func SplitRequirement(req string) (name, rng string, err error) {
+	fields := strings.SplitN(strings.TrimSpace(req), " ", 2)
+	if fields[0] == "" {
+		return "", "", fmt.Errorf("invalid requirement %q", req)
+	}
+	if len(fields) == 1 {
+		return fields[0], "", nil
+	}
+	return fields[0], strings.TrimSpace(fields[1]), nil
+}
+
+// purgeContribution removes name's prior selection and, recursively, every
+// constraint that selection's requirements placed on name's dependencies.
+// Without this, a name re-resolved because a later root requires an
+// incompatible range (the two-requested-plugins-share-a-dependency case)
+// would leave its abandoned candidate's ranges in constraints, which can
+// make an otherwise-solvable dependency graph look unsatisfiable.
+func purgeContribution(name string, selected map[string]plugin.PluginVersion, constraints map[string][]requirement) {
+	delete(selected, name)
+	for depName, reqs := range constraints {
+		filtered := make([]requirement, 0, len(reqs))
+		changed := false
+		for _, r := range reqs {
+			if r.requiredBy == name {
+				changed = true
+				continue
+			}
+			filtered = append(filtered, r)
+		}
+		if changed {
+			constraints[depName] = filtered
+			purgeContribution(depName, selected, constraints)
+		}
+	}
+}
+
+// cloneSelected returns a shallow copy of m, letting a caller restore a
+// prior resolution state without later mutations of the original map
+// being visible through the copy.
+func cloneSelected(m map[string]plugin.PluginVersion) map[string]plugin.PluginVersion {
+	clone := make(map[string]plugin.PluginVersion, len(m))
+	for k, v := range m {
+		clone[k] = v
+	}
+	return clone
+}
+
+// cloneConstraints returns a shallow copy of m, letting a caller restore a
+// prior resolution state without later mutations of the original map
+// being visible through the copy.
+func cloneConstraints(m map[string][]requirement) map[string][]requirement {
+	clone := make(map[string][]requirement, len(m))
+	for k, v := range m {
+		clone[k] = v
+	}
+	return clone
+}
+
+// satisfiesAll reports whether versionStr satisfies every range collected
+// for a name so far, i.e. the intersection of all requirements placed on it.
+func satisfiesAll(reqs []requirement, versionStr string) bool {
+	for _, r := range reqs {
+		if !rangeMatches(r.rng, versionStr) {
+			return false
+		}
+	}
+	return true
+}
+
+// conflictError reports the requirement responsible for name having no
+// satisfiable version: the first one that, on its own, no catalog version
+// matches, or (if every range is individually satisfiable but none overlap)
+// the most recently added one.
+func conflictError(name string, pkg plugin.PluginPackage, reqs []requirement) error {
+	versions := sortedVersions(pkg.PluginVersions)
+	for _, r := range reqs {
+		matched := false
+		for _, v := range versions {
+			if rangeMatches(r.rng, v.Version) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return &ResolutionError{Plugin: r.requiredBy, Dependency: name, Range: r.rng}
+		}
+	}
+
+	last := reqs[len(reqs)-1]
+	return &ResolutionError{Plugin: last.requiredBy, Dependency: name, Range: last.rng}
+}
+
+func rangeMatches(rng, versionStr string) bool {
+	if rng == "" {
+		return true
+	}
+	r, err := version.ParseRange(rng)
+	if err != nil {
+		return false
+	}
+	v, err := version.Parse(versionStr)
+	if err != nil {
+		return false
+	}
+	return r.Matches(v)
+}
+
+// sortedVersions returns candidate versions newest-first so Resolve prefers
+// the newest version satisfying every constraint.
+func sortedVersions(versions []plugin.PluginVersion) []plugin.PluginVersion {
+	sorted := make([]plugin.PluginVersion, len(versions))
+	copy(sorted, versions)
+	sort.Slice(sorted, func(i, j int) bool {
+		vi, erri := version.Parse(sorted[i].Version)
+		vj, errj := version.Parse(sorted[j].Version)
+		if erri != nil || errj != nil {
+			return sorted[i].Version > sorted[j].Version
+		}
+		return vi.Compare(vj) > 0
+	})
+	return sorted
+}
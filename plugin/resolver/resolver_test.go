@@ -0,0 +1,192 @@
+package resolver
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/totmicro/mops-sdk/plugin"
+	"github.com/totmicro/mops-sdk/version"
+)
+
+func pkgOf(name string, versions ...plugin.PluginVersion) plugin.PluginPackage {
+	return plugin.PluginPackage{Name: name, PluginVersions: versions}
+}
+
+func TestResolveSimpleChain(t *testing.T) {
+	catalog := Catalog{
+		"a": pkgOf("a", plugin.PluginVersion{Version: "1.0.0", Requires: []string{"b >=1.0.0"}}),
+		"b": pkgOf("b",
+			plugin.PluginVersion{Version: "1.0.0"},
+			plugin.PluginVersion{Version: "2.0.0"},
+		),
+	}
+
+	selected, err := Resolve([]string{"a"}, catalog)
+	if err != nil {
+		t.Fatalf("Resolve returned error: %v", err)
+	}
+	if got := selected["b"].Version; got != "2.0.0" {
+		t.Errorf("b = %s, want newest matching version 2.0.0", got)
+	}
+}
+
+func TestResolveBacktracksToSatisfyBothSiblings(t *testing.T) {
+	// a and b both require shared, but only 1.5.0 satisfies both; the
+	// resolver must back off from the newest shared candidate (2.5.0) to
+	// find it.
+	catalog := Catalog{
+		"a": pkgOf("a", plugin.PluginVersion{Version: "1.0.0", Requires: []string{"shared >=1.0.0"}}),
+		"b": pkgOf("b", plugin.PluginVersion{Version: "1.0.0", Requires: []string{"shared <2.0.0"}}),
+		"shared": pkgOf("shared",
+			plugin.PluginVersion{Version: "1.5.0"},
+			plugin.PluginVersion{Version: "2.5.0"},
+		),
+	}
+
+	selected, err := Resolve([]string{"a", "b"}, catalog)
+	if err != nil {
+		t.Fatalf("Resolve returned error: %v", err)
+	}
+	if got := selected["shared"].Version; got != "1.5.0" {
+		t.Errorf("shared = %s, want 1.5.0 (the only version satisfying both a and b)", got)
+	}
+}
+
+func TestResolveConflictNamesTheSharedDependency(t *testing.T) {
+	// a requires shared>=2.0.0, b requires shared<2.0.0: no version of
+	// shared satisfies both, even though a and b are each trivially
+	// satisfiable on their own. The error must blame "shared", not
+	// whichever of a/b happened to be resolved last.
+	catalog := Catalog{
+		"a": pkgOf("a", plugin.PluginVersion{Version: "1.0.0", Requires: []string{"shared >=2.0.0"}}),
+		"b": pkgOf("b", plugin.PluginVersion{Version: "1.0.0", Requires: []string{"shared <2.0.0"}}),
+		"shared": pkgOf("shared",
+			plugin.PluginVersion{Version: "1.5.0"},
+			plugin.PluginVersion{Version: "2.5.0"},
+		),
+	}
+
+	for _, requested := range [][]string{{"a", "b"}, {"b", "a"}} {
+		_, err := Resolve(requested, catalog)
+		if err == nil {
+			t.Fatalf("Resolve(%v) expected a conflict error, got nil", requested)
+		}
+		var resErr *ResolutionError
+		if !errors.As(err, &resErr) {
+			t.Fatalf("Resolve(%v) error is not a *ResolutionError: %v", requested, err)
+		}
+		if resErr.Dependency != "shared" {
+			t.Errorf("Resolve(%v) blamed %q, want the real conflicting dependency %q", requested, resErr.Dependency, "shared")
+		}
+	}
+}
+
+func TestResolveMissingPackage(t *testing.T) {
+	catalog := Catalog{
+		"a": pkgOf("a", plugin.PluginVersion{Version: "1.0.0", Requires: []string{"missing >=1.0.0"}}),
+	}
+
+	_, err := Resolve([]string{"a"}, catalog)
+	var resErr *ResolutionError
+	if !errors.As(err, &resErr) {
+		t.Fatalf("Resolve returned %v, want a *ResolutionError", err)
+	}
+	if resErr.Dependency != "missing" {
+		t.Errorf("Dependency = %q, want %q", resErr.Dependency, "missing")
+	}
+}
+
+func TestResolveDependencyCycle(t *testing.T) {
+	// b requires a version of a that the candidate of a currently being
+	// resolved doesn't satisfy, forcing a to be revisited while still
+	// marked as visiting.
+	catalog := Catalog{
+		"a": pkgOf("a", plugin.PluginVersion{Version: "1.0.0", Requires: []string{"b >=1.0.0"}}),
+		"b": pkgOf("b", plugin.PluginVersion{Version: "1.0.0", Requires: []string{"a >=2.0.0"}}),
+	}
+
+	_, err := Resolve([]string{"a"}, catalog)
+	if err == nil {
+		t.Fatal("Resolve expected a cycle error, got nil")
+	}
+}
+
+func TestResolveClearsStaleConstraintsOnReResolution(t *testing.T) {
+	// a requires shared>=1.0.0 and b requires shared<2.0.0, so a is
+	// resolved first and picks shared@2.0.0, committing depX>=2.0.0 along
+	// the way. b then forces shared down to 1.0.0, which requires
+	// depX=1.0.0 instead; the stale depX>=2.0.0 constraint from the
+	// discarded shared@2.0.0 pick must not linger and make depX look
+	// unsatisfiable.
+	catalog := Catalog{
+		"a": pkgOf("a", plugin.PluginVersion{Version: "1.0.0", Requires: []string{"shared >=1.0.0"}}),
+		"b": pkgOf("b", plugin.PluginVersion{Version: "1.0.0", Requires: []string{"shared <2.0.0"}}),
+		"shared": pkgOf("shared",
+			plugin.PluginVersion{Version: "1.0.0", Requires: []string{"depX =1.0.0"}},
+			plugin.PluginVersion{Version: "2.0.0", Requires: []string{"depX >=2.0.0"}},
+		),
+		"depX": pkgOf("depX",
+			plugin.PluginVersion{Version: "1.0.0"},
+			plugin.PluginVersion{Version: "2.0.0"},
+		),
+	}
+
+	selected, err := Resolve([]string{"a", "b"}, catalog)
+	if err != nil {
+		t.Fatalf("Resolve returned error on a solvable graph: %v", err)
+	}
+	if got := selected["shared"].Version; got != "1.0.0" {
+		t.Errorf("shared = %s, want 1.0.0 (the only version satisfying both a and b)", got)
+	}
+	if got := selected["depX"].Version; got != "1.0.0" {
+		t.Errorf("depX = %s, want 1.0.0 (shared@1.0.0's requirement)", got)
+	}
+}
+
+func TestResolveRequiresCompatibleCore(t *testing.T) {
+	catalog := Catalog{
+		"a": pkgOf("a", plugin.PluginVersion{Version: "1.0.0", Requires: []string{corePluginName + " >=9.0.0"}}),
+	}
+
+	_, err := Resolve([]string{"a"}, catalog)
+	var resErr *ResolutionError
+	if !errors.As(err, &resErr) {
+		t.Fatalf("Resolve returned %v, want a *ResolutionError", err)
+	}
+	if resErr.Dependency != corePluginName {
+		t.Errorf("Dependency = %q, want %q", resErr.Dependency, corePluginName)
+	}
+	if rangeMatches(">=9.0.0", version.CurrentAPIVersion) {
+		t.Fatalf("test assumption broken: CurrentAPIVersion %s now satisfies >=9.0.0", version.CurrentAPIVersion)
+	}
+}
+
+func TestSplitRequirement(t *testing.T) {
+	tests := []struct {
+		req     string
+		name    string
+		rng     string
+		wantErr bool
+	}{
+		{"logging-plugin >=2.0.0", "logging-plugin", ">=2.0.0", false},
+		{"logging-plugin", "logging-plugin", "", false},
+		{"  spaced  >=1.0.0  ", "spaced", ">=1.0.0", false},
+		{"", "", "", true},
+	}
+
+	for _, tt := range tests {
+		name, rng, err := SplitRequirement(tt.req)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("SplitRequirement(%q) expected error, got nil", tt.req)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("SplitRequirement(%q) returned error: %v", tt.req, err)
+		}
+		if name != tt.name || rng != tt.rng {
+			t.Errorf("SplitRequirement(%q) = (%q, %q), want (%q, %q)", tt.req, name, rng, tt.name, tt.rng)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+// Package store implements a content-addressed blobstore for plugin
+// archives: every archive is kept on disk under its sha256 digest, so a
+// plugin.DependencyInfo.Hash can be verified by recomputing it from the
+// stored blob rather than trusted blindly. plugin.BuildInfo.PluginHash is
+// verified the same way against a caller-supplied digest, typically of the
+// bundled .so rather than the whole archive (the manifest carrying the hash
+// lives inside the archive, so hashing the archive itself is a fixed point
+// no one could ever have authored).
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/totmicro/mops-sdk/plugin"
+)
+
+// Store is a content-addressed blobstore rooted at a single directory.
+type Store struct {
+	root string
+}
+
+// New creates a Store backed by root, creating it if necessary.
+func New(root string) (*Store, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("create store root %s: %w", root, err)
+	}
+	return &Store{root: root}, nil
+}
+
+// Put copies src into the store under its sha256 digest and returns that
+// digest, hex-encoded and unprefixed.
+func (s *Store) Put(src string) (string, error) {
+	digest, err := Digest(src)
+	if err != nil {
+		return "", err
+	}
+
+	dest := s.Path(digest)
+	if _, err := os.Stat(dest); err == nil {
+		return digest, nil // already stored
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return "", err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+
+	tmp := dest + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return "", err
+	}
+	if err := out.Close(); err != nil {
+		return "", err
+	}
+
+	if err := os.Rename(tmp, dest); err != nil {
+		return "", err
+	}
+	return digest, nil
+}
+
+// Path returns the on-disk location of the blob with the given digest,
+// sharded by its first byte to keep any one directory from growing huge.
+func (s *Store) Path(digest string) string {
+	if len(digest) < 2 {
+		return filepath.Join(s.root, digest)
+	}
+	return filepath.Join(s.root, digest[:2], digest)
+}
+
+// Has reports whether a blob with the given digest is already stored.
+func (s *Store) Has(digest string) bool {
+	_, err := os.Stat(s.Path(digest))
+	return err == nil
+}
+
+// Verify recomputes the digest of the stored blob and reports whether it
+// still matches its own content-address, catching on-disk corruption or
+// tampering.
+func (s *Store) Verify(digest string) error {
+	actual, err := Digest(s.Path(digest))
+	if err != nil {
+		return fmt.Errorf("verify %s: %w", digest, err)
+	}
+	if actual != digest {
+		return fmt.Errorf("blob %s is corrupt: recomputed digest %s", digest, actual)
+	}
+	return nil
+}
+
+// Digest computes the sha256 digest of a file, hex-encoded and unprefixed.
+func Digest(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// VerifyPluginHash compares digest against info.PluginHash, returning an
+// error on mismatch or if no hash was recorded to verify against. digest
+// must be computed from whatever PluginHash was actually recorded against
+// (e.g. the plugin's compiled .so), not the archive as a whole.
+func VerifyPluginHash(digest string, info *plugin.BuildInfo) error {
+	if info == nil || info.PluginHash == "" {
+		return fmt.Errorf("no plugin hash recorded to verify against")
+	}
+	if digest != info.PluginHash {
+		return fmt.Errorf("plugin hash mismatch: manifest says %s, computed %s", info.PluginHash, digest)
+	}
+	return nil
+}
+
+// VerifyDependencies checks every dependency with a declared hash against
+// the digest of its own blob in the store, catching a tampered or
+// substituted transitive dependency.
+func (s *Store) VerifyDependencies(deps []plugin.DependencyInfo) error {
+	for _, dep := range deps {
+		if dep.Hash == "" {
+			continue
+		}
+		if !s.Has(dep.Hash) {
+			return fmt.Errorf("dependency %s: blob %s is not in the store", dep.Name, dep.Hash)
+		}
+		if err := s.Verify(dep.Hash); err != nil {
+			return fmt.Errorf("dependency %s: %w", dep.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package version
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Range represents a semver constraint expression, e.g. ">=1.2.0 <2.0.0" or
+// "^1.4.0 || ~2.1.0". A version matches a Range if it satisfies every
+// comparator in at least one of the OR-separated comparator sets.
+type Range struct {
+	raw  string
+	sets [][]comparator
+}
+
+// comparator is a single operator/version pair within a comparator set, e.g.
+// the ">=1.2.0" half of ">=1.2.0 <2.0.0".
+type comparator struct {
+	op      string
+	version *Version
+}
+
+// ParseRange parses a semver range expression supporting the >=, <=, >, <,
+// =, ^ (caret), ~ (tilde), and || (OR) operators.
+func ParseRange(s string) (*Range, error) {
+	raw := strings.TrimSpace(s)
+	if raw == "" {
+		return nil, fmt.Errorf("range string cannot be empty")
+	}
+
+	var sets [][]comparator
+	for _, part := range strings.Split(raw, "||") {
+		set, err := parseComparatorSet(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid range %q: %w", s, err)
+		}
+		sets = append(sets, set)
+	}
+
+	return &Range{raw: raw, sets: sets}, nil
+}
+
+// String returns the original range expression.
+func (r *Range) String() string {
+	return r.raw
+}
+
+// Matches reports whether v satisfies at least one of the range's
+// comparator sets.
+func (r *Range) Matches(v *Version) bool {
+	for _, set := range r.sets {
+		if matchesSet(set, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesSet(set []comparator, v *Version) bool {
+	for _, c := range set {
+		if !c.matches(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c comparator) matches(v *Version) bool {
+	cmp := v.Compare(c.version)
+	switch c.op {
+	case ">=":
+		return cmp >= 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case "<":
+		return cmp < 0
+	case "=":
+		return cmp == 0
+	default:
+		return false
+	}
+}
+
+func parseComparatorSet(part string) ([]comparator, error) {
+	fields := strings.Fields(part)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty comparator set")
+	}
+
+	var set []comparator
+	for _, field := range fields {
+		cs, err := parseComparatorToken(field)
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, cs...)
+	}
+	return set, nil
+}
+
+// parseComparatorToken parses a single space-delimited token. ^ and ~ expand
+// to a pair of >=/< comparators bounding the allowed range.
+func parseComparatorToken(token string) ([]comparator, error) {
+	switch {
+	case strings.HasPrefix(token, "^"):
+		v, err := Parse(token[1:])
+		if err != nil {
+			return nil, err
+		}
+		return []comparator{{op: ">=", version: v}, {op: "<", version: caretUpperBound(v)}}, nil
+
+	case strings.HasPrefix(token, "~"):
+		v, err := Parse(token[1:])
+		if err != nil {
+			return nil, err
+		}
+		return []comparator{{op: ">=", version: v}, {op: "<", version: tildeUpperBound(v)}}, nil
+
+	case strings.HasPrefix(token, ">="):
+		v, err := Parse(token[2:])
+		if err != nil {
+			return nil, err
+		}
+		return []comparator{{op: ">=", version: v}}, nil
+
+	case strings.HasPrefix(token, "<="):
+		v, err := Parse(token[2:])
+		if err != nil {
+			return nil, err
+		}
+		return []comparator{{op: "<=", version: v}}, nil
+
+	case strings.HasPrefix(token, ">"):
+		v, err := Parse(token[1:])
+		if err != nil {
+			return nil, err
+		}
+		return []comparator{{op: ">", version: v}}, nil
+
+	case strings.HasPrefix(token, "<"):
+		v, err := Parse(token[1:])
+		if err != nil {
+			return nil, err
+		}
+		return []comparator{{op: "<", version: v}}, nil
+
+	case strings.HasPrefix(token, "="):
+		v, err := Parse(token[1:])
+		if err != nil {
+			return nil, err
+		}
+		return []comparator{{op: "=", version: v}}, nil
+
+	default:
+		v, err := Parse(token)
+		if err != nil {
+			return nil, err
+		}
+		return []comparator{{op: "=", version: v}}, nil
+	}
+}
+
+// caretUpperBound returns the exclusive upper bound for ^v: changes are
+// allowed as long as the left-most non-zero component is unchanged.
+func caretUpperBound(v *Version) *Version {
+	switch {
+	case v.Major > 0:
+		return &Version{Major: v.Major + 1}
+	case v.Minor > 0:
+		return &Version{Major: 0, Minor: v.Minor + 1}
+	default:
+		return &Version{Major: 0, Minor: 0, Patch: v.Patch + 1}
+	}
+}
+
+// tildeUpperBound returns the exclusive upper bound for ~v: patch-level
+// changes are allowed, minor is pinned.
+func tildeUpperBound(v *Version) *Version {
+	return &Version{Major: v.Major, Minor: v.Minor + 1}
+}
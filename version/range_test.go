@@ -0,0 +1,60 @@
+package version
+
+import "testing"
+
+func TestRangeMatches(t *testing.T) {
+	tests := []struct {
+		name  string
+		rng   string
+		vers  string
+		match bool
+	}{
+		{"gte satisfied", ">=1.2.0", "1.2.0", true},
+		{"gte below", ">=1.2.0", "1.1.9", false},
+		{"lt satisfied", "<2.0.0", "1.99.99", true},
+		{"lt equal excluded", "<2.0.0", "2.0.0", false},
+		{"and both satisfied", ">=1.2.0 <2.0.0", "1.5.0", true},
+		{"and one fails", ">=1.2.0 <2.0.0", "2.0.0", false},
+		{"caret within major", "^1.4.0", "1.9.9", true},
+		{"caret crosses major", "^1.4.0", "2.0.0", false},
+		{"caret below floor", "^1.4.0", "1.3.9", false},
+		{"tilde within minor", "~1.4.0", "1.4.9", true},
+		{"tilde crosses minor", "~1.4.0", "1.5.0", false},
+		{"or first set matches", "1.0.0 || 2.0.0", "1.0.0", true},
+		{"or second set matches", "1.0.0 || 2.0.0", "2.0.0", true},
+		{"or neither matches", "1.0.0 || 2.0.0", "1.5.0", false},
+		{"bare equality", "1.2.3", "1.2.3", true},
+		{"bare equality mismatch", "1.2.3", "1.2.4", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := ParseRange(tt.rng)
+			if err != nil {
+				t.Fatalf("ParseRange(%q) returned error: %v", tt.rng, err)
+			}
+			v, err := Parse(tt.vers)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", tt.vers, err)
+			}
+			if got := r.Matches(v); got != tt.match {
+				t.Errorf("Range(%q).Matches(%q) = %v, want %v", tt.rng, tt.vers, got, tt.match)
+			}
+		})
+	}
+}
+
+func TestParseRangeInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"   ",
+		">=not-a-version",
+		"1.2.0 ||",
+	}
+
+	for _, rng := range tests {
+		if _, err := ParseRange(rng); err == nil {
+			t.Errorf("ParseRange(%q) expected error, got nil", rng)
+		}
+	}
+}
@@ -131,27 +131,23 @@ func (v *Version) Compare(other *Version) int {
 
 // IsCompatible checks if this version is compatible with the target version range
 func (v *Version) IsCompatible(minVersion, maxVersion string) (bool, error) {
+	var tokens []string
 	if minVersion != "" {
-		min, err := Parse(minVersion)
-		if err != nil {
-			return false, fmt.Errorf("invalid min version: %w", err)
-		}
-		if v.Compare(min) < 0 {
-			return false, nil
-		}
+		tokens = append(tokens, ">="+minVersion)
 	}
-
 	if maxVersion != "" {
-		max, err := Parse(maxVersion)
-		if err != nil {
-			return false, fmt.Errorf("invalid max version: %w", err)
-		}
-		if v.Compare(max) > 0 {
-			return false, nil
-		}
+		tokens = append(tokens, "<="+maxVersion)
+	}
+	if len(tokens) == 0 {
+		return true, nil
+	}
+
+	r, err := ParseRange(strings.Join(tokens, " "))
+	if err != nil {
+		return false, fmt.Errorf("invalid version range: %w", err)
 	}
 
-	return true, nil
+	return r.Matches(v), nil
 }
 
 // CheckAPICompatibility checks if a plugin API version is compatible with MOPS